@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net"
+
+	netutils "k8s.io/utils/net"
+)
+
+// CIDROverlap returns true if the parsed forms of a and b overlap: either
+// one contains the other's network address, or they are otherwise not
+// disjoint. It returns false (rather than an error) if either CIDR fails to
+// parse, since callers that care about parse errors should validate with
+// ValidateNodeNetworkCIDRs first.
+func CIDROverlap(a, b string) bool {
+	_, aNet, err := net.ParseCIDR(a)
+	if err != nil {
+		return false
+	}
+	_, bNet, err := net.ParseCIDR(b)
+	if err != nil {
+		return false
+	}
+
+	return aNet.Contains(bNet.IP) || bNet.Contains(aNet.IP)
+}
+
+// ValidateNodeNetworkCIDRs validates a set of CIDRs that are all meant to
+// describe disjoint address ranges for a node's networking configuration
+// (e.g. the pod CIDR, service CIDR, and node/switch CIDRs used by kubelet and
+// cloud-provider/CNI plugins). It returns an error if any CIDR fails to
+// parse, if more than one CIDR is given for the same IP family (a dual-stack
+// configuration may have at most one IPv4 and one IPv6 CIDR), or if any pair
+// of CIDRs overlap.
+func ValidateNodeNetworkCIDRs(cidrs ...string) error {
+	var v4Count, v6Count int
+	parsed := make([]string, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("failed to parse CIDR %q: %v", cidr, err)
+		}
+
+		for _, other := range parsed {
+			if CIDROverlap(cidr, other) {
+				return fmt.Errorf("CIDR %q overlaps with %q", cidr, other)
+			}
+		}
+
+		if netutils.IsIPv4(ip) {
+			v4Count++
+		} else {
+			v6Count++
+		}
+		if v4Count > 1 || v6Count > 1 {
+			return fmt.Errorf("only one CIDR is allowed per IP family (beyond a dual-stack pair), found duplicate for %q", cidr)
+		}
+
+		parsed = append(parsed, cidr)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCIDROverlap(t *testing.T) {
+	testCases := []struct {
+		desc string
+		a, b string
+		want bool
+	}{
+		{desc: "disjoint IPv4", a: "10.0.0.0/24", b: "10.0.1.0/24", want: false},
+		{desc: "identical IPv4", a: "10.0.0.0/24", b: "10.0.0.0/24", want: true},
+		{desc: "a contains b", a: "10.0.0.0/16", b: "10.0.1.0/24", want: true},
+		{desc: "b contains a", a: "10.0.1.0/24", b: "10.0.0.0/16", want: true},
+		{desc: "different families never overlap", a: "10.0.0.0/24", b: "2001::/64", want: false},
+		{desc: "unparseable a", a: "garbage", b: "10.0.0.0/24", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := CIDROverlap(tc.a, tc.b); got != tc.want {
+				t.Errorf("CIDROverlap(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateNodeNetworkCIDRs(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		cidrs []string
+		err   string
+	}{
+		{desc: "empty", cidrs: nil},
+		{desc: "single CIDR", cidrs: []string{"10.0.0.0/24"}},
+		{desc: "dual-stack pair", cidrs: []string{"10.0.0.0/24", "2001::/64"}},
+		{desc: "unparseable", cidrs: []string{"garbage"}, err: "failed to parse CIDR"},
+		{desc: "duplicate IPv4 family", cidrs: []string{"10.0.0.0/24", "10.1.0.0/24"}, err: "only one CIDR is allowed per IP family"},
+		{desc: "duplicate IPv6 family", cidrs: []string{"2001::/64", "2002::/64"}, err: "only one CIDR is allowed per IP family"},
+		{desc: "overlapping pod and service CIDRs", cidrs: []string{"10.0.0.0/16", "10.0.1.0/24"}, err: "overlaps"},
+		{desc: "three non-overlapping CIDRs, two families", cidrs: []string{"10.0.0.0/24", "2001::/64", "10.1.0.0/24"}, err: "only one CIDR is allowed per IP family"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateNodeNetworkCIDRs(tc.cidrs...)
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
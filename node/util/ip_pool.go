@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	netutils "k8s.io/utils/net"
+)
+
+// interfaceAddrs is overridden in tests so ResolveNodeIPPool doesn't depend on
+// the addresses actually configured on the machine running the test.
+var interfaceAddrs = net.InterfaceAddrs
+
+// NodeIPPool represents a single entry of a --node-ip value that names a
+// range of addresses (a CIDR block or a dashed address range); a bare literal
+// address is held as a single-address pool. The node's actual IP is
+// determined later, by picking whichever locally-configured address falls
+// inside the pool.
+type NodeIPPool struct {
+	first net.IP
+	last  net.IP
+}
+
+// String returns the canonical representation of p: a single address if p is
+// a degenerate single-address pool (e.g. from a bare literal IP), or a
+// dashed range otherwise.
+func (p *NodeIPPool) String() string {
+	if p.first.Equal(p.last) {
+		return p.first.String()
+	}
+	return fmt.Sprintf("%s-%s", p.first, p.last)
+}
+
+// Contains returns true if ip falls within the (inclusive) bounds of the pool.
+func (p *NodeIPPool) Contains(ip net.IP) bool {
+	ip = ip.To16()
+	if ip == nil || len(p.first) != len(p.last) {
+		return false
+	}
+	first, last := p.first.To16(), p.last.To16()
+	return bytes.Compare(ip, first) >= 0 && bytes.Compare(ip, last) <= 0
+}
+
+// isIPv4 returns true if the pool's bounds are IPv4 addresses.
+func (p *NodeIPPool) isIPv4() bool {
+	return netutils.IsIPv4(p.first)
+}
+
+// isUnspecified returns true if the pool is a degenerate, single-address
+// pool for the unspecified address ('0.0.0.0' or '::'), which (like a bare
+// unspecified literal IP) is never a usable node IP.
+func (p *NodeIPPool) isUnspecified() bool {
+	return p.first.Equal(p.last) && p.first.IsUnspecified()
+}
+
+// parseNodeIPPoolToken parses a single comma-separated element of a --node-ip
+// value as a pool: either a CIDR block ("10.0.0.0/24") or a dashed address
+// range ("10.0.0.10-10.0.0.20"). It returns (nil, false, nil) if the token
+// isn't a recognized pool syntax, leaving it to the caller to try parsing it
+// as a plain IP instead, and (nil, true, err) if it looks like a pool but is
+// malformed.
+func parseNodeIPPoolToken(token string) (*NodeIPPool, bool, error) {
+	if strings.Contains(token, "/") {
+		_, ipNet, err := net.ParseCIDR(token)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to parse %q as a CIDR block: %v", token, err)
+		}
+		first := ipNet.IP.Mask(ipNet.Mask)
+		last := make(net.IP, len(first))
+		for i := range first {
+			last[i] = first[i] | ^ipNet.Mask[i]
+		}
+		return &NodeIPPool{first: first, last: last}, true, nil
+	}
+
+	if strings.Contains(token, "-") {
+		parts := strings.SplitN(token, "-", 2)
+		first := netutils.ParseIPSloppy(strings.TrimSpace(parts[0]))
+		last := netutils.ParseIPSloppy(strings.TrimSpace(parts[1]))
+		if first == nil || last == nil {
+			return nil, true, fmt.Errorf("failed to parse %q as an IP address range", token)
+		}
+		if netutils.IsIPv4(first) != netutils.IsIPv4(last) {
+			return nil, true, fmt.Errorf("failed to parse %q as an IP address range: endpoints must be the same family", token)
+		}
+		if bytes.Compare(first.To16(), last.To16()) > 0 {
+			return nil, true, fmt.Errorf("failed to parse %q as an IP address range: start is after end", token)
+		}
+		return &NodeIPPool{first: first, last: last}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// ParseNodeIPPool parses a --node-ip-style value that may contain CIDR blocks
+// and/or dashed address ranges (in addition to bare IPs, which are treated as
+// single-address pools) and returns the resulting pools. It applies the same
+// dual-stack rules as ParseNodeIPArgument: at most one IPv4 and one IPv6 pool
+// may be given, and (unless permitted by cloudProvider/allowCloudDualStack) a
+// dual-stack pair is rejected.
+//
+// The caller is expected to pass the result to ResolveNodeIPPool, along with
+// the set of addresses actually configured on the node, to determine which
+// concrete address to use.
+func ParseNodeIPPool(nodeIP, cloudProvider string, allowCloudDualStack bool) ([]*NodeIPPool, error) {
+	var pools []*NodeIPPool
+	for _, token := range strings.Split(nodeIP, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		pool, isPool, err := parseNodeIPPoolToken(token)
+		if err != nil {
+			return nil, err
+		}
+		if !isPool {
+			ip := netutils.ParseIPSloppy(token)
+			if ip == nil {
+				continue
+			}
+			pool = &NodeIPPool{first: ip, last: ip}
+		}
+
+		pools = append(pools, pool)
+	}
+
+	switch len(pools) {
+	case 0:
+		return nil, nil
+	case 1:
+		return pools, nil
+	case 2:
+		if pools[0].isIPv4() == pools[1].isIPv4() {
+			return nil, fmt.Errorf("failed to parse --node-ip value %q: either a single pool or a dual-stack pair of pools is required", nodeIP)
+		}
+		if !nodeIPDualStackAllowed(cloudProvider, allowCloudDualStack) {
+			return nil, fmt.Errorf("dual-stack --node-ip %q is not supported in this configuration", nodeIP)
+		}
+		if pools[0].isUnspecified() || pools[1].isUnspecified() {
+			return nil, fmt.Errorf("failed to parse --node-ip value %q: dual-stack node IPs cannot include '0.0.0.0' or '::'", nodeIP)
+		}
+		return pools, nil
+	default:
+		return nil, fmt.Errorf("failed to parse --node-ip value %q: either a single pool or a dual-stack pair of pools is required", nodeIP)
+	}
+}
+
+// ResolveNodeIPPool picks, for each pool in pools, the first locally-configured
+// interface address that falls inside it, and returns the resulting addresses
+// in the same order as pools. It returns an error if any pool has no matching
+// local address.
+func ResolveNodeIPPool(pools []*NodeIPPool) ([]net.IP, error) {
+	if len(pools) == 0 {
+		return nil, nil
+	}
+
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("could not list local interface addresses: %v", err)
+	}
+	var local []net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		local = append(local, ipNet.IP)
+	}
+
+	var resolved []net.IP
+	for _, pool := range pools {
+		found := false
+		for _, ip := range local {
+			if pool.Contains(ip) {
+				resolved = append(resolved, ip)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no local address found in node IP pool %s", pool)
+		}
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	netutils "k8s.io/utils/net"
+)
+
+func TestParseNodeIPPool(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		want int
+		err  string
+	}{
+		{desc: "empty", in: "", want: 0},
+		{desc: "single IP", in: "1.2.3.4", want: 1},
+		{desc: "single CIDR", in: "10.0.0.0/24", want: 1},
+		{desc: "single range", in: "10.0.0.10-10.0.0.20", want: 1},
+		{desc: "dual-stack CIDRs", in: "10.0.0.0/24,2001::/64", want: 2},
+		{desc: "mixed CIDR and range", in: "10.0.0.0/24,2001::10-2001::14", want: 2},
+		{desc: "invalid CIDR", in: "10.0.0.0/99", err: "failed to parse"},
+		{desc: "backwards range", in: "10.0.0.20-10.0.0.10", err: "start is after end"},
+		{desc: "mixed-family range", in: "10.0.0.10-2001::14", err: "must be the same family"},
+		{desc: "two IPv4 pools", in: "10.0.0.0/24,10.1.0.0/24", err: "either a single pool or a dual-stack pair"},
+		{desc: "dual-stack with unspecified literal", in: "0.0.0.0,abcd::1", err: "cannot include '0.0.0.0' or '::'"},
+		{desc: "dual-stack with unspecified literal, other family", in: "1.2.3.4,::", err: "cannot include '0.0.0.0' or '::'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pools, err := ParseNodeIPPool(tc.in, cloudProviderNone, false)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(pools) != tc.want {
+				t.Fatalf("expected %d pools, got %d", tc.want, len(pools))
+			}
+		})
+	}
+}
+
+func TestNodeIPPoolContains(t *testing.T) {
+	pools, err := ParseNodeIPPool("10.0.0.0/24", cloudProviderNone, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool := pools[0]
+
+	if !pool.Contains(netutils.ParseIPSloppy("10.0.0.1")) {
+		t.Errorf("expected pool to contain 10.0.0.1")
+	}
+	if !pool.Contains(netutils.ParseIPSloppy("10.0.0.255")) {
+		t.Errorf("expected pool to contain 10.0.0.255 (broadcast)")
+	}
+	if pool.Contains(netutils.ParseIPSloppy("10.0.1.1")) {
+		t.Errorf("expected pool not to contain 10.0.1.1")
+	}
+}
+
+func TestNodeIPPoolString(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{
+		{desc: "bare literal IP", in: "9.9.9.9", want: "9.9.9.9"},
+		{desc: "range", in: "10.0.0.10-10.0.0.20", want: "10.0.0.10-10.0.0.20"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pools, err := ParseNodeIPPool(tc.in, cloudProviderNone, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := pools[0].String(); got != tc.want {
+				t.Errorf("String() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveNodeIPPool(t *testing.T) {
+	restore := interfaceAddrs
+	defer func() { interfaceAddrs = restore }()
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: netutils.ParseIPSloppy("10.0.0.5"), Mask: net.CIDRMask(24, 32)},
+			&net.IPNet{IP: netutils.ParseIPSloppy("2001::5"), Mask: net.CIDRMask(64, 128)},
+		}, nil
+	}
+
+	pools, err := ParseNodeIPPool("10.0.0.0/24,2001::/64", cloudProviderNone, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resolved, err := ResolveNodeIPPool(pools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolved) != 2 || !resolved[0].Equal(netutils.ParseIPSloppy("10.0.0.5")) || !resolved[1].Equal(netutils.ParseIPSloppy("2001::5")) {
+		t.Errorf("unexpected resolved addresses: %v", resolved)
+	}
+
+	pools, err = ParseNodeIPPool("10.1.0.0/24", cloudProviderNone, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ResolveNodeIPPool(pools); err == nil {
+		t.Errorf("expected error when no local address matches the pool")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package util contains node-related utility functions shared by kubelet,
+// cloud providers, and other components that need to reason about node
+// addressing.
+package util
+
+import "net"
+
+// Recognized values of the kubelet's --cloud-provider flag that affect how
+// dual-stack node IPs are handled.
+const (
+	cloudProviderNone     = ""
+	cloudProviderExternal = "external"
+)
+
+// nodeIPDualStackAllowed returns true if the given cloud-provider configuration
+// permits a dual-stack node IP value. Dual-stack node IPs are always allowed
+// when no cloud provider is in use, and are allowed for the external cloud
+// provider only when the caller opts in (e.g. via a feature gate); any other
+// (legacy, in-tree) cloud provider never supports it.
+func nodeIPDualStackAllowed(cloudProvider string, allowCloudDualStack bool) bool {
+	switch cloudProvider {
+	case cloudProviderNone:
+		return true
+	case cloudProviderExternal:
+		return allowCloudDualStack
+	default:
+		return false
+	}
+}
+
+// ParseNodeIPArgument reads the --node-ip argument and parses and validates it,
+// building and returning a list of net.IPs. Some number of bad/unparseable
+// addresses may be ignored (with a warning message), but if the flag contains
+// no usable addresses at all, then it will return (nil, nil).
+//
+// If cloudProvider is not empty, then this assumes that it is being called
+// with the output of the --cloud-provider flag, and will apply special rules
+// used by kubelet for that flag: dual-stack node IPs are only supported when
+// there is no cloud provider, or when the cloud provider is "external" and
+// allowCloudDualStack is true.
+//
+// This is a thin wrapper around NodeIPSpec, configured to match --node-ip's
+// historical parsing behavior; callers that need to reuse the same rules
+// elsewhere (CLI flags, config files) should use NodeIPSpec directly.
+func ParseNodeIPArgument(nodeIP, cloudProvider string, allowCloudDualStack bool) ([]net.IP, error) {
+	spec := NodeIPSpec{
+		WhitespaceAllowed: true,
+		IgnoreGarbage:     true,
+		AllowDualStack:    allowCloudDualStack,
+		CloudProvider:     cloudProvider,
+	}
+	if err := spec.Parse(nodeIP); err != nil {
+		return nil, err
+	}
+	return spec.IPs, nil
+}
+
+// ParseNodeIPAnnotation parses the alpha.kubernetes.io/provided-node-ip
+// annotation, which unlike --node-ip, must contain exactly one IP address
+// (or, in the case of a dual-stack annotation that we can't actually use, an
+// appropriate error). It is stricter than ParseNodeIPArgument: rather than
+// ignoring unparseable or whitespace-padded values, it rejects them.
+//
+// This is a thin wrapper around NodeIPSpec, configured to match the
+// annotation's historical (single-stack-only, no tolerance for garbage)
+// parsing behavior.
+func ParseNodeIPAnnotation(annotation string) (net.IP, error) {
+	spec := NodeIPSpec{
+		WhitespaceAllowed: false,
+		IgnoreGarbage:     false,
+		AllowDualStack:    false,
+		CloudProvider:     cloudProviderDualStackNever,
+	}
+	if err := spec.Parse(annotation); err != nil {
+		return nil, err
+	}
+	// Parse only succeeds with an empty spec.IPs when garbage was silently
+	// ignored, which can't happen with IgnoreGarbage: false, so spec.IPs is
+	// guaranteed to hold exactly one address here.
+	return spec.IPs[0], nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+	netutils "k8s.io/utils/net"
+)
+
+var _ pflag.Value = &NodeIPSpec{}
+
+// cloudProviderDualStackNever is not a valid --cloud-provider value (real
+// values are either empty or a recognized provider name). It is used
+// internally by ParseNodeIPAnnotation to configure a NodeIPSpec that never
+// permits a dual-stack value, since (unlike --node-ip) the node IP
+// annotation has never supported dual-stack addresses.
+const cloudProviderDualStackNever = "<none>"
+
+// NodeIPSpec holds a parsed node-IP preference plus the flags that control
+// how it is parsed, serialized, and validated. It generalizes the two
+// historical entry points, ParseNodeIPArgument (the kubelet --node-ip flag)
+// and ParseNodeIPAnnotation (the alpha.kubernetes.io/provided-node-ip
+// annotation), so that other config surfaces (kubeadm/kops-style validation,
+// config-file loaders, CLI flags) can share one implementation and one
+// on-disk representation instead of round-tripping through ad-hoc strings.
+type NodeIPSpec struct {
+	// IPs holds the addresses produced by the most recent successful Parse.
+	IPs []net.IP
+
+	// WhitespaceAllowed causes Parse to trim leading/trailing whitespace from
+	// each comma-separated value before parsing it as an IP.
+	WhitespaceAllowed bool
+	// IgnoreGarbage causes Parse to silently drop values that aren't valid IP
+	// addresses, rather than failing outright.
+	IgnoreGarbage bool
+	// AllowDualStack corresponds to the allowCloudDualStack argument of
+	// ParseNodeIPArgument: whether the caller has opted in (e.g. via a
+	// feature gate) to a dual-stack value when CloudProvider is the external
+	// cloud provider. It has no effect when CloudProvider is empty (dual
+	// stack is always allowed) or set to any other cloud provider (dual
+	// stack is never allowed).
+	AllowDualStack bool
+	// CloudProvider is the node's configured --cloud-provider value, or
+	// empty if none. It constrains whether a dual-stack value is permitted;
+	// see AllowDualStack.
+	CloudProvider string
+}
+
+// Parse parses value as a comma-separated node IP preference, using s's
+// configured flags, and stores the result in s.IPs. It returns an error if
+// value cannot be parsed into either a single IP or a dual-stack pair of
+// IPs, or if a resulting dual-stack pair isn't permitted by s.CloudProvider
+// and s.AllowDualStack. If s.IgnoreGarbage is set and no value parses as an
+// IP, Parse returns nil and sets s.IPs to nil.
+func (s *NodeIPSpec) Parse(value string) error {
+	var ips []net.IP
+	for _, token := range strings.Split(value, ",") {
+		if s.WhitespaceAllowed {
+			token = strings.TrimSpace(token)
+		}
+
+		ip := netutils.ParseIPSloppy(token)
+		if ip == nil {
+			if s.IgnoreGarbage {
+				klog.InfoS("Could not parse node IP value as an IP address, ignoring", "value", token)
+				continue
+			}
+			return fmt.Errorf("could not parse %q as an IP address", value)
+		}
+		ips = append(ips, ip)
+	}
+
+	switch len(ips) {
+	case 0:
+		s.IPs = nil
+		return nil
+	case 1:
+		s.IPs = ips
+		return nil
+	case 2:
+		if netutils.IsIPv4(ips[0]) == netutils.IsIPv4(ips[1]) {
+			return fmt.Errorf("failed to parse %q: either a single IP or a dual-stack pair of IPs is required", value)
+		}
+		if !nodeIPDualStackAllowed(s.CloudProvider, s.AllowDualStack) {
+			return fmt.Errorf("dual-stack value %q is not supported in this configuration", value)
+		}
+		if ips[0].IsUnspecified() || ips[1].IsUnspecified() {
+			return fmt.Errorf("failed to parse %q: dual-stack IPs cannot include '0.0.0.0' or '::'", value)
+		}
+		s.IPs = ips
+		return nil
+	default:
+		return fmt.Errorf("failed to parse %q: either a single IP or a dual-stack pair of IPs is required", value)
+	}
+}
+
+// String returns the comma-separated representation of s.IPs.
+func (s *NodeIPSpec) String() string {
+	if s == nil {
+		return ""
+	}
+	strs := make([]string, len(s.IPs))
+	for i, ip := range s.IPs {
+		strs[i] = ip.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+// Set parses value and updates s.IPs, implementing pflag.Value so a
+// NodeIPSpec can be registered directly as a CLI flag.
+func (s *NodeIPSpec) Set(value string) error {
+	return s.Parse(value)
+}
+
+// Type implements pflag.Value.
+func (s *NodeIPSpec) Type() string {
+	return "nodeIPSpec"
+}
+
+// MarshalJSON marshals s as its comma-separated string representation, so
+// that a NodeIPSpec embedded in a config type round-trips through YAML/JSON
+// the same way the string-typed fields it replaces used to.
+func (s NodeIPSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON parses a JSON string via Parse, preserving whichever parsing
+// flags (WhitespaceAllowed, IgnoreGarbage, AllowDualStack, CloudProvider)
+// were already set on s.
+func (s *NodeIPSpec) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return s.Parse(value)
+}
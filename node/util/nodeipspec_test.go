@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestNodeIPSpecParseAndString(t *testing.T) {
+	spec := &NodeIPSpec{WhitespaceAllowed: true, IgnoreGarbage: true, AllowDualStack: true}
+	if err := spec.Parse(" 1.2.3.4 , abcd::1 "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := spec.String(), "1.2.3.4,abcd::1"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestNodeIPSpecPflagValue(t *testing.T) {
+	spec := &NodeIPSpec{WhitespaceAllowed: true, IgnoreGarbage: true}
+	var _ pflag.Value = spec
+
+	if err := spec.Set("1.2.3.4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := spec.String(), "1.2.3.4"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if spec.Type() == "" {
+		t.Errorf("Type() should not be empty")
+	}
+}
+
+func TestNodeIPSpecJSON(t *testing.T) {
+	spec := &NodeIPSpec{WhitespaceAllowed: true, IgnoreGarbage: true, AllowDualStack: true}
+	if err := spec.Parse("1.2.3.4,abcd::1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(data), `"1.2.3.4,abcd::1"`; got != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	var roundTripped NodeIPSpec
+	roundTripped.AllowDualStack = true
+	if err := json.Unmarshal(data, &roundTripped); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roundTripped.String() != spec.String() {
+		t.Errorf("round-tripped spec = %q, want %q", roundTripped.String(), spec.String())
+	}
+}
+
+func TestNodeIPSpecCloudProviderDualStack(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		cloudProvider  string
+		allowDualStack bool
+		wantErr        string
+	}{
+		{desc: "no cloud provider always allows dual-stack", cloudProvider: cloudProviderNone, allowDualStack: false},
+		{desc: "external cloud provider requires opt-in", cloudProvider: cloudProviderExternal, allowDualStack: false, wantErr: "not supported in this configuration"},
+		{desc: "external cloud provider with opt-in", cloudProvider: cloudProviderExternal, allowDualStack: true},
+		{desc: "legacy in-tree cloud provider never allows dual-stack", cloudProvider: "gce", allowDualStack: true, wantErr: "not supported in this configuration"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			spec := &NodeIPSpec{WhitespaceAllowed: true, IgnoreGarbage: true, AllowDualStack: tc.allowDualStack, CloudProvider: tc.cloudProvider}
+			err := spec.Parse("1.2.3.4,abcd::1")
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
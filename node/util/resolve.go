@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net"
+
+	netutils "k8s.io/utils/net"
+)
+
+// ResolveNodeIP determines the node IP(s) to use given the user's --node-ip /
+// provided-node-ip preference and the addresses discovered for the node (by
+// the host's network interfaces, or by a cloud provider's NodeAddresses
+// call). It is the shared implementation behind kubelet's --node-ip flag and
+// the alpha.kubernetes.io/provided-node-ip annotation, and applies the same
+// dual-stack / cloud-provider rules as ParseNodeIPArgument and
+// ParseNodeIPPool.
+//
+// If nodeIPArg is empty, ResolveNodeIP returns candidates unchanged, provided
+// they are a single IP or a dual-stack pair that is permitted in this
+// configuration (see validateResolvedNodeIPs); otherwise it returns an
+// error. If nodeIPArg is not empty, it is parsed as either a set of literal
+// IPs (via ParseNodeIPArgument) or a set of CIDR/range pools (via
+// ParseNodeIPPool resolved against candidates), and the resulting address(es)
+// are returned in place of the bare candidate list.
+func ResolveNodeIP(nodeIPArg, cloudProvider string, allowCloudDualStack bool, candidates []net.IP) ([]net.IP, error) {
+	if nodeIPArg == "" {
+		if err := validateResolvedNodeIPs(candidates, cloudProvider, allowCloudDualStack); err != nil {
+			return nil, err
+		}
+		return candidates, nil
+	}
+
+	literal, err := ParseNodeIPArgument(nodeIPArg, cloudProvider, allowCloudDualStack)
+	if err != nil {
+		return nil, err
+	}
+	if literal != nil {
+		return literal, nil
+	}
+
+	pools, err := ParseNodeIPPool(nodeIPArg, cloudProvider, allowCloudDualStack)
+	if err != nil {
+		return nil, err
+	}
+	if pools == nil {
+		// nodeIPArg didn't contain any recognizable literal IP or pool syntax;
+		// ParseNodeIPArgument/ParseNodeIPPool both treat that as ignorable
+		// garbage rather than an error, so we do too.
+		return nil, nil
+	}
+
+	return resolveNodeIPPoolFromCandidates(pools, candidates)
+}
+
+// validateResolvedNodeIPs applies the same family/dual-stack rules that
+// ParseNodeIPArgument and ParseNodeIPPool apply to a parsed --node-ip value
+// to a set of already-resolved addresses (e.g. the addresses discovered from
+// the host's network interfaces, or from a cloud provider's NodeAddresses
+// call), so that ResolveNodeIP enforces the same constraints regardless of
+// whether the caller expressed a preference via nodeIPArg.
+func validateResolvedNodeIPs(ips []net.IP, cloudProvider string, allowCloudDualStack bool) error {
+	switch len(ips) {
+	case 0, 1:
+		return nil
+	case 2:
+		if netutils.IsIPv4(ips[0]) == netutils.IsIPv4(ips[1]) {
+			return fmt.Errorf("either a single IP or a dual-stack pair of IPs is required, got %v", ips)
+		}
+		if !nodeIPDualStackAllowed(cloudProvider, allowCloudDualStack) {
+			return fmt.Errorf("dual-stack node IPs %v are not supported in this configuration", ips)
+		}
+		if ips[0].IsUnspecified() || ips[1].IsUnspecified() {
+			return fmt.Errorf("dual-stack node IPs cannot include '0.0.0.0' or '::': %v", ips)
+		}
+		return nil
+	default:
+		return fmt.Errorf("either a single IP or a dual-stack pair of IPs is required, got %v", ips)
+	}
+}
+
+// resolveNodeIPPoolFromCandidates is like ResolveNodeIPPool, but resolves
+// pools against an explicit candidate list (e.g. a cloud provider's
+// NodeAddresses) instead of the local machine's network interfaces.
+func resolveNodeIPPoolFromCandidates(pools []*NodeIPPool, candidates []net.IP) ([]net.IP, error) {
+	var resolved []net.IP
+	for _, pool := range pools {
+		found := false
+		for _, ip := range candidates {
+			if pool.Contains(ip) {
+				resolved = append(resolved, ip)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no candidate address found in node IP pool %s", pool)
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	netutils "k8s.io/utils/net"
+)
+
+func TestResolveNodeIP(t *testing.T) {
+	candidates := []net.IP{
+		netutils.ParseIPSloppy("10.0.0.5"),
+		netutils.ParseIPSloppy("2001::5"),
+	}
+
+	testCases := []struct {
+		desc string
+		in   string
+		out  []net.IP
+		err  string
+	}{
+		{
+			desc: "no preference falls back to candidates",
+			in:   "",
+			out:  candidates,
+		},
+		{
+			desc: "literal IP overrides candidates",
+			in:   "1.2.3.4",
+			out:  []net.IP{netutils.ParseIPSloppy("1.2.3.4")},
+		},
+		{
+			desc: "pool resolves against candidates",
+			in:   "10.0.0.0/24",
+			out:  []net.IP{netutils.ParseIPSloppy("10.0.0.5")},
+		},
+		{
+			desc: "dual-stack pool resolves each family",
+			in:   "10.0.0.0/24,2001::/64",
+			out:  candidates,
+		},
+		{
+			desc: "pool with no matching candidate",
+			in:   "10.1.0.0/24",
+			err:  "no candidate address found",
+		},
+		{
+			desc: "garbage is ignored, like ParseNodeIPArgument",
+			in:   "blah",
+			out:  nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			out, err := ResolveNodeIP(tc.in, cloudProviderNone, false, candidates)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(out, tc.out) {
+				t.Errorf("expected %#v, got %#v", tc.out, out)
+			}
+		})
+	}
+}
+
+func TestResolveNodeIPEmptyArgValidatesCandidates(t *testing.T) {
+	dualStack := []net.IP{
+		netutils.ParseIPSloppy("10.0.0.5"),
+		netutils.ParseIPSloppy("2001::5"),
+	}
+
+	testCases := []struct {
+		desc                string
+		candidates          []net.IP
+		cloudProvider       string
+		allowCloudDualStack bool
+		err                 string
+	}{
+		{
+			desc:          "single candidate is always fine",
+			candidates:    dualStack[:1],
+			cloudProvider: cloudProviderNone,
+		},
+		{
+			desc:          "dual-stack candidates allowed with no cloud provider",
+			candidates:    dualStack,
+			cloudProvider: cloudProviderNone,
+		},
+		{
+			desc:          "dual-stack candidates rejected for unsupported cloud provider",
+			candidates:    dualStack,
+			cloudProvider: "gce",
+			err:           "not supported in this configuration",
+		},
+		{
+			desc:                "dual-stack candidates rejected for external cloud provider without opt-in",
+			candidates:          dualStack,
+			cloudProvider:       cloudProviderExternal,
+			allowCloudDualStack: false,
+			err:                 "not supported in this configuration",
+		},
+		{
+			desc:                "dual-stack candidates allowed for external cloud provider with opt-in",
+			candidates:          dualStack,
+			cloudProvider:       cloudProviderExternal,
+			allowCloudDualStack: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			out, err := ResolveNodeIP("", tc.cloudProvider, tc.allowCloudDualStack, tc.candidates)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(out, tc.candidates) {
+				t.Errorf("expected %#v, got %#v", tc.candidates, out)
+			}
+		})
+	}
+}